@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Node is the formatter-agnostic representation of a call graph node,
+// carrying just enough metadata (package, receiver, exported/goroot flags)
+// for any Formatter to render it without reaching back into go/ssa.
+type Node struct {
+	ID        string
+	Label     string
+	Pkg       string
+	PkgName   string
+	Recv      string
+	IsClosure bool
+	Exported  bool
+	Goroot    bool
+	InFocus   bool
+
+	// Profile overlay; zero unless -profile was given.
+	FlatPercent float64
+	CumPercent  float64
+	CumSamples  int64
+}
+
+// Edge is the formatter-agnostic representation of a call graph edge.
+type Edge struct {
+	From       *Node
+	To         *Node
+	Dynamic    bool
+	Go         bool
+	Defer      bool
+	CrossFocus bool
+}
+
+// Graph is the intermediate model printOutput builds once per run; every
+// Formatter renders from it instead of walking the callgraph itself.
+type Graph struct {
+	Title     string
+	FocusPkg  string
+	GroupPkg  bool
+	GroupType bool
+	Nodes     []*Node
+	Edges     []*Edge
+}
+
+// Formatter renders a Graph to w in some output dialect.
+type Formatter interface {
+	Render(w io.Writer, g *Graph) error
+}
+
+var formatters = map[string]Formatter{
+	"dot":  dotFormatter{},
+	"mmd":  mermaidFormatter{},
+	"puml": plantumlFormatter{},
+	"txt":  asciiFormatter{},
+	"json": jsonFormatter{},
+}
+
+// formatAliases maps every spelling a user might reasonably type — either
+// as -format or as a -file extension — to the canonical key formatters is
+// registered under, so both paths agree on what's a valid value.
+var formatAliases = map[string]string{
+	"dot":      "dot",
+	"gv":       "dot",
+	"mmd":      "mmd",
+	"mermaid":  "mmd",
+	"puml":     "puml",
+	"plantuml": "puml",
+	"txt":      "txt",
+	"ascii":    "txt",
+	"json":     "json",
+}
+
+// formatterFor resolves the Formatter to use: an explicit -format always
+// wins, otherwise the extension of file picks it, the way go-opera's
+// export command decides its codec from .gz vs .dot.
+func formatterFor(file, explicit string) (Formatter, error) {
+	name, ok := formatAliases[strings.ToLower(explicit)]
+	if !ok {
+		if explicit != "" {
+			return nil, fmt.Errorf("unknown output format %q", explicit)
+		}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+		name, ok = formatAliases[ext]
+		if !ok {
+			name = "dot"
+		}
+	}
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q", explicit)
+	}
+	return f, nil
+}
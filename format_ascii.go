@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// asciiFormatter renders a Graph as a plain box-drawing call tree, similar
+// to iftree's text output, rooted at every node with no caller in the graph.
+type asciiFormatter struct{}
+
+func (asciiFormatter) Render(w io.Writer, g *Graph) error {
+	children := make(map[string][]*Node)
+	hasCaller := make(map[string]bool)
+	for _, e := range g.Edges {
+		children[e.From.ID] = append(children[e.From.ID], e.To)
+		hasCaller[e.To.ID] = true
+	}
+	for _, kids := range children {
+		sort.Slice(kids, func(i, j int) bool { return kids[i].Label < kids[j].Label })
+	}
+
+	var roots []*Node
+	for _, n := range g.Nodes {
+		if !hasCaller[n.ID] {
+			roots = append(roots, n)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].Label < roots[j].Label })
+
+	visited := make(map[string]bool)
+	var walk func(n *Node, prefix string, last bool)
+	walk = func(n *Node, prefix string, last bool) {
+		branch := "├── "
+		if last {
+			branch = "└── "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, n.Label)
+		if visited[n.ID] {
+			return
+		}
+		visited[n.ID] = true
+
+		childPrefix := prefix + "│   "
+		if last {
+			childPrefix = prefix + "    "
+		}
+		kids := children[n.ID]
+		for i, k := range kids {
+			walk(k, childPrefix, i == len(kids)-1)
+		}
+	}
+
+	for i, r := range roots {
+		fmt.Fprintln(w, r.Label)
+		visited[r.ID] = true
+		kids := children[r.ID]
+		for j, k := range kids {
+			walk(k, "", j == len(kids)-1)
+		}
+		if i != len(roots)-1 {
+			fmt.Fprintln(w)
+		}
+	}
+
+	return nil
+}
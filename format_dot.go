@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	"io"
+)
+
+// dotFormatter renders a Graph as a Graphviz DOT document. It reproduces
+// the clustering and styling printOutput has always produced, just driven
+// off the generic Graph model instead of walking the callgraph directly.
+type dotFormatter struct{}
+
+// dotID turns an arbitrary function signature string into a syntactically
+// valid, collision-safe Graphviz node ID. Raw signatures can contain
+// brackets (generics), spaces (synthetic wrapper thunks) and other
+// characters a DOT ID can't carry unquoted, so every node gets one of
+// these instead of its raw node.Func.String() form.
+func dotID(raw string) string {
+	h := fnv.New64a()
+	h.Write([]byte(raw))
+	return fmt.Sprintf("n%x", h.Sum64())
+}
+
+func (dotFormatter) Render(w io.Writer, g *Graph) error {
+	cluster := NewDotCluster("focus")
+	cluster.Attrs = dotAttrs{
+		"label":     g.FocusPkg,
+		"bgcolor":   "aliceblue",
+		"labelloc":  "t",
+		"labeljust": "c",
+		"fontsize":  "18",
+	}
+
+	nodes := []*dotNode{}
+	edges := []*dotEdge{}
+	nodeMap := make(map[string]*dotNode)
+
+	for _, n := range g.Nodes {
+		attrs := make(dotAttrs)
+		label := n.Label
+
+		// omit type from label
+		if g.GroupType && n.Recv != "" {
+			parts := strings.Split(label, ".")
+			label = parts[len(parts)-1]
+		}
+
+		// set node color
+		if n.Goroot {
+			attrs["fillcolor"] = "#adedad"
+		} else if n.InFocus {
+			attrs["fillcolor"] = "lightblue"
+		} else {
+			attrs["fillcolor"] = "wheat"
+		}
+
+		// include pkg name
+		if !g.GroupPkg && !n.InFocus {
+			label = fmt.Sprintf("%s\n%s", n.PkgName, label)
+		}
+
+		// overlay pprof weights, if any were loaded
+		if n.CumSamples > 0 || n.FlatPercent > 0 {
+			attrs["xlabel"] = fmt.Sprintf("flat %.1f%% / cum %.1f%%", n.FlatPercent, n.CumPercent)
+			attrs["fillcolor"] = heatColor(n.CumPercent)
+		}
+		attrs["label"] = label
+
+		// func styles
+		if n.IsClosure {
+			attrs["style"] = "dotted,filled"
+		} else if n.Exported {
+			attrs["penwidth"] = "1.5"
+		} else {
+			attrs["penwidth"] = "0.5"
+		}
+
+		c := cluster
+
+		// group by pkg
+		if g.GroupPkg && !n.InFocus {
+			clabel := n.PkgName
+			if n.Goroot {
+				clabel = n.Pkg
+			}
+			pkgKey := n.Pkg
+			if _, ok := c.Clusters[pkgKey]; !ok {
+				c.Clusters[pkgKey] = &dotCluster{
+					ID:       dotID(pkgKey),
+					Clusters: make(map[string]*dotCluster),
+					Attrs: dotAttrs{
+						"penwidth":  "0.8",
+						"fontsize":  "16",
+						"label":     clabel,
+						"style":     "filled",
+						"fillcolor": "snow",
+					},
+				}
+				if n.Goroot {
+					c.Clusters[pkgKey].Attrs["fillcolor"] = "#E0FFE1"
+				}
+			}
+			c = c.Clusters[pkgKey]
+		}
+
+		// group by type
+		if g.GroupType && n.Recv != "" {
+			fillclr := "lemonchiffon"
+			if n.InFocus {
+				fillclr = "lavender"
+			}
+			recvKey := n.Recv
+			if _, ok := c.Clusters[recvKey]; !ok {
+				c.Clusters[recvKey] = &dotCluster{
+					ID:       dotID(recvKey),
+					Clusters: make(map[string]*dotCluster),
+					Attrs: dotAttrs{
+						"penwidth":  "0.5",
+						"fontsize":  "15",
+						"fontcolor": "#222222",
+						"label":     strings.Split(n.Label, ".")[0],
+						"labelloc":  "b",
+						"style":     "rounded,filled",
+						"fillcolor": fillclr,
+					},
+				}
+				if n.Goroot {
+					c.Clusters[recvKey].Attrs["fillcolor"] = "#c4ecc4"
+				}
+			}
+			c = c.Clusters[recvKey]
+		}
+
+		// n.ID (node.Func.String()) is the lookup key for nodeMap below; it
+		// is NOT a valid Graphviz ID on its own (generics carry "[T]",
+		// synthetic wrappers carry spaces), so the DOT node — and, for the
+		// same reason, every dotCluster built from a package path or
+		// receiver type string above — gets a separate, hash-derived ID
+		// instead of reusing the raw string verbatim.
+		dn := &dotNode{ID: dotID(n.ID), Attrs: attrs}
+		if c != nil {
+			c.Nodes = append(c.Nodes, dn)
+		} else {
+			nodes = append(nodes, dn)
+		}
+		nodeMap[n.ID] = dn
+	}
+
+	for _, e := range g.Edges {
+		attrs := make(dotAttrs)
+
+		if e.Dynamic {
+			attrs["style"] = "dashed"
+		}
+		if e.Go {
+			attrs["arrowhead"] = "normalnoneodot"
+		} else if e.Defer {
+			attrs["arrowhead"] = "normalnoneodiamond"
+		}
+		if e.CrossFocus {
+			attrs["color"] = "saddlebrown"
+		}
+		if e.To.CumSamples > 0 {
+			attrs["penwidth"] = fmt.Sprintf("%.1f", 1+math.Log1p(float64(e.To.CumSamples)))
+		}
+
+		edges = append(edges, &dotEdge{
+			From:  nodeMap[e.From.ID],
+			To:    nodeMap[e.To.ID],
+			Attrs: attrs,
+		})
+	}
+
+	dot := &dotGraph{
+		Title:   g.Title,
+		Minlen:  minlen,
+		Cluster: cluster,
+		Nodes:   nodes,
+		Edges:   edges,
+		Options: map[string]string{
+			"minlen":  fmt.Sprint(minlen),
+			"nodesep": fmt.Sprint(nodesep),
+		},
+	}
+
+	return WriteDot(w, dot)
+}
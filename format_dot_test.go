@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDotFormatterDistinctIDsForSameName renders a graph with a
+// package-level function and a method that share a display name (and so
+// would collide if the formatter keyed DOT nodes off label text), and
+// checks each still gets its own valid, distinct DOT node ID.
+func TestDotFormatterDistinctIDsForSameName(t *testing.T) {
+	fn := &Node{
+		ID:    "example.com/pkg.Close",
+		Label: "Close",
+		Pkg:   "example.com/pkg",
+	}
+	method := &Node{
+		ID:    "example.com/pkg.(*Client).Close",
+		Label: "Close",
+		Pkg:   "example.com/pkg",
+		Recv:  "*example.com/pkg.Client",
+	}
+
+	g := &Graph{
+		Title:     "example.com/pkg",
+		GroupType: true, // exercises the recvKey cluster branch method falls into and fn doesn't
+		Nodes:     []*Node{fn, method},
+		Edges:     []*Edge{{From: fn, To: method}},
+	}
+
+	var buf bytes.Buffer
+	if err := (dotFormatter{}).Render(&buf, g); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	idFn, idMethod := dotID(fn.ID), dotID(method.ID)
+	if idFn == idMethod {
+		t.Fatalf("expected distinct DOT IDs for %q and %q, got the same hash %q", fn.ID, method.ID, idFn)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, idFn) {
+		t.Fatalf("rendered DOT missing node ID %q for %q:\n%s", idFn, fn.ID, out)
+	}
+	if !strings.Contains(out, idMethod) {
+		t.Fatalf("rendered DOT missing node ID %q for %q:\n%s", idMethod, method.ID, out)
+	}
+
+	// method.Recv feeds the group-by-type cluster key this commit renamed
+	// from the original shadowed "key" to "recvKey" and hashed via dotID;
+	// assert the cluster the method actually landed under carries that ID.
+	recvClusterID := dotID(method.Recv)
+	if !strings.Contains(out, recvClusterID) {
+		t.Fatalf("rendered DOT missing group-by-type cluster ID %q for receiver %q:\n%s", recvClusterID, method.Recv, out)
+	}
+}
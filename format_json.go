@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonFormatter dumps the Graph's title, nodes (id, label, package, receiver,
+// exported and goroot flags) and edges (with a single kind each: go, defer,
+// dynamic or static, in that order of precedence) so downstream tooling (jq,
+// editor plugins, custom dashboards) can consume the call graph without
+// parsing DOT. Closure, focus and profile fields are not emitted.
+type jsonFormatter struct{}
+
+type jsonNode struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Pkg      string `json:"pkg"`
+	Recv     string `json:"recv,omitempty"`
+	Exported bool   `json:"exported"`
+	Goroot   bool   `json:"goroot"`
+}
+
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+func (jsonFormatter) Render(w io.Writer, g *Graph) error {
+	doc := struct {
+		Title string     `json:"title"`
+		Nodes []jsonNode `json:"nodes"`
+		Edges []jsonEdge `json:"edges"`
+	}{Title: g.Title}
+
+	for _, n := range g.Nodes {
+		doc.Nodes = append(doc.Nodes, jsonNode{
+			ID:       n.ID,
+			Label:    n.Label,
+			Pkg:      n.Pkg,
+			Recv:     n.Recv,
+			Exported: n.Exported,
+			Goroot:   n.Goroot,
+		})
+	}
+
+	for _, e := range g.Edges {
+		kind := "static"
+		switch {
+		case e.Go:
+			kind = "go"
+		case e.Defer:
+			kind = "defer"
+		case e.Dynamic:
+			kind = "dynamic"
+		}
+		doc.Edges = append(doc.Edges, jsonEdge{From: e.From.ID, To: e.To.ID, Kind: kind})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// mermaidFormatter renders a Graph as a Mermaid flowchart, so it can be
+// dropped straight into a ```mermaid fence in a Markdown doc.
+type mermaidFormatter struct{}
+
+func (mermaidFormatter) Render(w io.Writer, g *Graph) error {
+	fmt.Fprintln(w, "flowchart TD")
+
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		fmt.Fprintf(w, "    %s[%q]\n", id, n.Label)
+	}
+
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Dynamic {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(w, "    %s %s %s\n", ids[e.From.ID], arrow, ids[e.To.ID])
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// plantumlFormatter renders a Graph as a PlantUML component diagram.
+type plantumlFormatter struct{}
+
+func (plantumlFormatter) Render(w io.Writer, g *Graph) error {
+	fmt.Fprintln(w, "@startuml")
+	if g.Title != "" {
+		fmt.Fprintf(w, "title %s\n", g.Title)
+	}
+
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("C%d", i)
+		ids[n.ID] = id
+		fmt.Fprintf(w, "component %q as %s\n", n.Label, id)
+	}
+
+	for _, e := range g.Edges {
+		style := "-->"
+		if e.Dynamic {
+			style = "..>"
+		}
+		fmt.Fprintf(w, "%s %s %s\n", ids[e.From.ID], style, ids[e.To.ID])
+	}
+
+	fmt.Fprintln(w, "@enduml")
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+)
+
+// Direction selects which side of a call edge CallHierarchy walks.
+type Direction int
+
+const (
+	// DirectionIncoming walks callers of the target (who calls this?).
+	DirectionIncoming Direction = iota
+	// DirectionOutgoing walks callees of the target (what does this call?).
+	DirectionOutgoing
+)
+
+// Tree is a bounded caller/callee hierarchy rooted at one function, the
+// same shape gopls' call_hierarchy resolver returns for editor plugins.
+type Tree struct {
+	Node     *callgraph.Node
+	Children []*Tree
+}
+
+// CallHierarchy resolves target in cg and walks its edges to depth levels
+// in dir, deduplicating cycles along each path. It is the library entry
+// point printHierarchy and editor plugins both call into.
+func CallHierarchy(cg *callgraph.Graph, target string, dir Direction, depth int) (*Tree, error) {
+	root, err := findNode(cg, target)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := make(map[*callgraph.Node]bool)
+	var walk func(n *callgraph.Node, remaining int) *Tree
+	walk = func(n *callgraph.Node, remaining int) *Tree {
+		t := &Tree{Node: n}
+		if remaining <= 0 || visited[n] {
+			return t
+		}
+		visited[n] = true
+		defer delete(visited, n)
+
+		edges := n.In
+		if dir == DirectionOutgoing {
+			edges = n.Out
+		}
+
+		seen := make(map[*callgraph.Node]bool)
+		for _, e := range edges {
+			next := e.Caller
+			if dir == DirectionOutgoing {
+				next = e.Callee
+			}
+			if next.Func.Synthetic != "" || seen[next] {
+				continue
+			}
+			seen[next] = true
+			t.Children = append(t.Children, walk(next, remaining-1))
+		}
+		return t
+	}
+
+	return walk(root, depth), nil
+}
+
+// findNode locates the *callgraph.Node whose function matches target. An
+// exact match (the full String() form, e.g. "example.com/pkg.(*Type).Method";
+// the package-relative RelString() form printOutput uses for node labels,
+// e.g. "(*Type).Method" or "Func"; or the "pkg.Type.Method" form, package
+// name plus RelString without parentheses and "*") always
+// wins; only when nothing matches exactly does it fall back to a suffix
+// match against RelString (so "Close" resolves a method defined on some
+// type). cg.Nodes is a Go map with randomized iteration order, so a suffix
+// match against more than one candidate is reported as an ambiguity error
+// rather than silently returning whichever one the map handed back first.
+func findNode(cg *callgraph.Graph, target string) (*callgraph.Node, error) {
+	stripRecv := strings.NewReplacer("(", "", ")", "", "*", "")
+	for fn, n := range cg.Nodes {
+		if fn == nil || fn.Pkg == nil {
+			continue
+		}
+		rel := fn.RelString(fn.Pkg.Pkg)
+		dotted := fn.Pkg.Pkg.Name() + "." + stripRecv.Replace(rel)
+		if fn.String() == target || rel == target || dotted == target {
+			return n, nil
+		}
+	}
+
+	var matches []*callgraph.Node
+	var labels []string
+	for fn, n := range cg.Nodes {
+		if fn == nil || fn.Pkg == nil {
+			continue
+		}
+		rel := fn.RelString(fn.Pkg.Pkg)
+		if strings.HasSuffix(rel, "."+target) {
+			matches = append(matches, n)
+			labels = append(labels, rel)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("hierarchy: target %q not found in call graph", target)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(labels)
+		return nil, fmt.Errorf("hierarchy: target %q is ambiguous, matches %s", target, strings.Join(labels, ", "))
+	}
+}
+
+// filterTree prunes children whose edge to t fails the -focus/-limit/-ignore
+// filters printOutput applies to the full graph, applied here after the
+// traversal instead of during it so CallHierarchy itself stays a pure,
+// filter-free library call. -focus matches printOutput's edge filter
+// (output.go): an edge survives if *either* endpoint is in focusPkg, not
+// just the child, so "-hierarchy=outgoing -focus=mypkg" still keeps a
+// callee outside mypkg when the caller (t) is itself in focus.
+func filterTree(t *Tree, focusPkg, limitPath string, ignorePaths []string) *Tree {
+	if t == nil {
+		return t
+	}
+
+	parentInFocus := focusPkg != "" && t.Node.Func.Pkg != nil && t.Node.Func.Pkg.Pkg.Name() == focusPkg
+
+	kept := t.Children[:0]
+	for _, c := range t.Children {
+		if c.Node.Func.Pkg == nil {
+			continue
+		}
+		pkg := c.Node.Func.Pkg.Pkg
+		if focusPkg != "" && pkg.Name() != focusPkg && !parentInFocus {
+			continue
+		}
+		pkgPath := pkg.Path()
+		if limitPath != "" && !strings.HasPrefix(pkgPath, limitPath) {
+			continue
+		}
+		skip := false
+		for _, p := range ignorePaths {
+			if strings.HasPrefix(pkgPath, p) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+		kept = append(kept, filterTree(c, focusPkg, limitPath, ignorePaths))
+	}
+	t.Children = kept
+	return t
+}
+
+// hierarchyGraph flattens a Tree into the same Graph model printOutput
+// builds, so the result can be handed to any registered Formatter.
+func hierarchyGraph(title string, t *Tree, dir Direction) *Graph {
+	g := &Graph{Title: title}
+	nodeMap := make(map[string]*Node)
+
+	var toNode func(n *callgraph.Node) *Node
+	toNode = func(n *callgraph.Node) *Node {
+		key := n.Func.String()
+		if existing, ok := nodeMap[key]; ok {
+			return existing
+		}
+		gn := &Node{
+			ID:      key,
+			Label:   n.Func.RelString(n.Func.Pkg.Pkg),
+			Pkg:     n.Func.Pkg.Pkg.Path(),
+			PkgName: n.Func.Pkg.Pkg.Name(),
+		}
+		g.Nodes = append(g.Nodes, gn)
+		nodeMap[key] = gn
+		return gn
+	}
+
+	var walk func(t *Tree)
+	walk = func(t *Tree) {
+		from := toNode(t.Node)
+		for _, c := range t.Children {
+			to := toNode(c.Node)
+			e := &Edge{From: from, To: to}
+			if dir == DirectionIncoming {
+				e.From, e.To = to, from
+			}
+			g.Edges = append(g.Edges, e)
+			walk(c)
+		}
+	}
+	walk(t)
+	return g
+}
+
+// printHierarchy resolves target in cg, walks it per dir/depth honoring the
+// same -focus/-limit/-ignore filters as printOutput, then feeds the result
+// through the same Formatter pipeline.
+func printHierarchy(cg *callgraph.Graph, target string, dir Direction, depth int, focusPkg, limitPath string, ignorePaths []string) error {
+	cg.DeleteSyntheticNodes()
+
+	t, err := CallHierarchy(cg, target, dir, depth)
+	if err != nil {
+		return err
+	}
+	t = filterTree(t, focusPkg, limitPath, ignorePaths)
+
+	g := hierarchyGraph(target, t, dir)
+
+	f, err := formatterFor(outputFile, format)
+	if err != nil {
+		return err
+	}
+	return f.Render(output, g)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/types"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/callgraph"
+)
+
+// interactiveSession holds the cached program/callgraph (built once) plus
+// the same render options printOutput normally reads from flags, so REPL
+// and HTTP commands can mutate them and re-render without rebuilding SSA.
+type interactiveSession struct {
+	mu sync.Mutex
+
+	mainPkg     *types.Package
+	cg          *callgraph.Graph
+	focusPkg    string
+	limitPath   string
+	ignorePaths []string
+	groupBy     map[string]bool
+	format      string
+	lastDOT     []byte
+}
+
+// runInteractive takes the callgraph the caller's existing build pipeline
+// built once and then drives it from stdin commands,
+// optionally also serving an HTML viewer at httpAddr.
+func runInteractive(mainPkg *types.Package, cg *callgraph.Graph, focusPkg, limitPath string, ignorePaths []string, groupBy map[string]bool, httpAddr string) error {
+	if groupBy == nil {
+		groupBy = make(map[string]bool)
+	} else {
+		copied := make(map[string]bool, len(groupBy))
+		for k, v := range groupBy {
+			copied[k] = v
+		}
+		groupBy = copied
+	}
+
+	s := &interactiveSession{
+		mainPkg:     mainPkg,
+		cg:          cg,
+		focusPkg:    focusPkg,
+		limitPath:   limitPath,
+		ignorePaths: append([]string(nil), ignorePaths...),
+		groupBy:     groupBy,
+		format:      "dot",
+	}
+
+	if httpAddr != "" {
+		go s.serveHTTP(httpAddr)
+		logf("interactive: serving http://%s", httpAddr)
+	}
+
+	fmt.Fprintln(os.Stderr, "go-callvis interactive mode, type 'help' for commands")
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := s.exec(line); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+	}
+}
+
+func (s *interactiveSession) exec(line string) error {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch cmd {
+	case "help":
+		fmt.Fprintln(os.Stderr, `commands:
+  focus <pkg>                     set -focus
+  ignore <path>                   add an -ignore path
+  limit <path>                    set -limit
+  group pkg|type                  toggle a -group flag
+  algo static|cha|rta|pointer     report the algorithm to rebuild with (requires restart)
+  hierarchy in|out <func> [depth] render a call hierarchy
+  format dot|mmd|puml|txt|json    set the output format
+  save <file>                     render and write to file
+  reset                           clear focus/limit/ignore/group back to defaults`)
+		return nil
+	case "focus":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: focus <pkg>")
+		}
+		s.focusPkg = args[0]
+	case "ignore":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: ignore <path>")
+		}
+		s.ignorePaths = append(s.ignorePaths, args[0])
+	case "limit":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: limit <path>")
+		}
+		s.limitPath = args[0]
+	case "group":
+		if len(args) != 1 || (args[0] != "pkg" && args[0] != "type") {
+			return fmt.Errorf("usage: group pkg|type")
+		}
+		s.groupBy[args[0]] = !s.groupBy[args[0]]
+	case "algo":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: algo static|cha|rta|pointer")
+		}
+		return fmt.Errorf("switching algorithms requires rebuilding the SSA program; restart with -algo=%s", args[0])
+	case "hierarchy":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: hierarchy in|out <func> [depth]")
+		}
+		dir := DirectionIncoming
+		if args[0] == "out" {
+			dir = DirectionOutgoing
+		}
+		depth := 3
+		if len(args) > 2 {
+			d, err := strconv.Atoi(args[2])
+			if err != nil {
+				return err
+			}
+			depth = d
+		}
+		t, err := CallHierarchy(s.cg, args[1], dir, depth)
+		if err != nil {
+			return err
+		}
+		t = filterTree(t, s.focusPkg, s.limitPath, s.ignorePaths)
+		f, err := formatterFor("", s.format)
+		if err != nil {
+			return err
+		}
+		return f.Render(os.Stdout, hierarchyGraph(args[1], t, dir))
+	case "format":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: format dot|mmd|puml|txt|json")
+		}
+		s.format = args[0]
+		return nil
+	case "save":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: save <file>")
+		}
+		out, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		return s.render(out)
+	case "reset":
+		s.focusPkg = ""
+		s.limitPath = ""
+		s.ignorePaths = nil
+		s.groupBy = map[string]bool{}
+	default:
+		return fmt.Errorf("unknown command %q, type 'help'", cmd)
+	}
+
+	return s.render(os.Stdout)
+}
+
+// render re-runs the graph-building half of printOutput against the cached
+// callgraph (no SSA rebuild) and writes the result to w.
+func (s *interactiveSession) render(w io.Writer) error {
+	var buf bytes.Buffer
+	prevOutput, prevFile, prevFormat := output, outputFile, format
+	output, outputFile, format = &buf, "", s.format
+	defer func() { output, outputFile, format = prevOutput, prevFile, prevFormat }()
+
+	if err := printOutput(s.mainPkg, s.cg, s.focusPkg, s.limitPath, s.ignorePaths, s.groupBy); err != nil {
+		return err
+	}
+
+	// only DOT output feeds the HTTP viewer's viz.js
+	if f, err := formatterFor("", s.format); err == nil {
+		if _, isDOT := f.(dotFormatter); isDOT {
+			s.lastDOT = append(s.lastDOT[:0], buf.Bytes()...)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// serveHTTP serves a page that loads the last-rendered graph into an
+// embedded viz.js, so a browser tab can stay open while REPL commands
+// mutate the view instead of shelling out to graphviz after every change.
+// The DOT text is attacker-controlled in general (it echoes identifiers
+// from whatever program go-callvis is pointed at), so it's passed through
+// html/template rather than fmt.Fprintf to get JS-string-context escaping
+// instead of Go's %q quoting, which does not escape "</script>".
+func (s *interactiveSession) serveHTTP(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		dot := string(s.lastDOT)
+		s.mu.Unlock()
+		if err := interactiveHTMLTemplate.Execute(w, dot); err != nil {
+			logf("interactive: rendering http page: %v", err)
+		}
+	})
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		logf("interactive: http server stopped: %v", err)
+	}
+}
+
+var interactiveHTMLTemplate = template.Must(template.New("interactive").Parse(`<!DOCTYPE html>
+<html>
+<head><title>go-callvis</title>
+<script src="https://cdn.jsdelivr.net/npm/viz.js@2/viz.js"></script>
+<script src="https://cdn.jsdelivr.net/npm/viz.js@2/full.render.js"></script>
+</head>
+<body>
+<div id="graph"></div>
+<script>
+new Viz().renderSVGElement({{.}})
+  .then(el => document.getElementById("graph").appendChild(el))
+  .catch(e => document.body.appendChild(document.createTextNode(String(e))));
+</script>
+</body>
+</html>`))
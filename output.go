@@ -14,24 +14,25 @@ import (
 
 var output io.Writer = os.Stdout
 
+// outputFile and format drive which Formatter printOutput hands the built
+// Graph to: format, if set, always wins; otherwise the extension of
+// outputFile picks it (see formatterFor).
+var outputFile string
+var format string
+
 func printOutput(mainPkg *types.Package, cg *callgraph.Graph, focusPkg, limitPath string, ignorePaths []string, groupBy map[string]bool) error {
 	groupType := groupBy["type"]
 	groupPkg := groupBy["pkg"]
 
-	cluster := NewDotCluster("focus")
-	cluster.Attrs = dotAttrs{
-		"label":     focusPkg,
-		"bgcolor":   "aliceblue",
-		"labelloc":  "t",
-		"labeljust": "c",
-		"fontsize":  "18",
+	graph := &Graph{
+		Title:     mainPkg.Path(),
+		FocusPkg:  focusPkg,
+		GroupPkg:  groupPkg,
+		GroupType: groupType,
 	}
 
-	nodes := []*dotNode{}
-	edges := []*dotEdge{}
-
-	nodeMap := make(map[string]*dotNode)
-	edgeMap := make(map[string]*dotEdge)
+	nodeMap := make(map[string]*Node)
+	edgeSeen := make(map[string]bool)
 
 	cg.DeleteSyntheticNodes()
 
@@ -67,163 +68,70 @@ func printOutput(mainPkg *types.Package, cg *callgraph.Graph, focusPkg, limitPat
 			}
 		}
 
-		var sprintNode = func(node *callgraph.Node) *dotNode {
+		var sprintNode = func(node *callgraph.Node) *Node {
 			// only once
 			key := node.Func.String()
 			if n, ok := nodeMap[key]; ok {
 				return n
 			}
 
-			attrs := make(dotAttrs)
-
-			// node label
-			label := node.Func.RelString(node.Func.Pkg.Pkg)
-
 			// func signature
 			sign := node.Func.Signature
 			if node.Func.Parent() != nil {
 				sign = node.Func.Parent().Signature
 			}
 
-			// omit type from label
-			if groupType && sign.Recv() != nil {
-				parts := strings.Split(label, ".")
-				label = parts[len(parts)-1]
+			recv := ""
+			if sign.Recv() != nil {
+				recv = sign.Recv().Type().String()
 			}
 
 			pkg, _ := build.Import(node.Func.Pkg.Pkg.Path(), "", 0)
-			// set node color
-			if pkg.Goroot {
-				attrs["fillcolor"] = "#adedad"
-			} else if node.Func.Pkg.Pkg.Name() == focusPkg {
-				attrs["fillcolor"] = "lightblue"
-			} else {
-				attrs["fillcolor"] = "wheat"
-			}
-
-			// include pkg name
-			if !groupPkg && node.Func.Pkg.Pkg.Name() != focusPkg {
-				label = fmt.Sprintf("%s\n%s", node.Func.Pkg.Pkg.Name(), label)
-			}
-
-			attrs["label"] = label
-
-			// func styles
-			if node.Func.Parent() != nil {
-				attrs["style"] = "dotted,filled"
-			} else if node.Func.Object() != nil && node.Func.Object().Exported() {
-				attrs["penwidth"] = "1.5"
-			} else {
-				attrs["penwidth"] = "0.5"
-			}
-
-			c := cluster
-
-			// group by pkg
-			if groupPkg && node.Func.Pkg.Pkg.Name() != focusPkg {
-				label := node.Func.Pkg.Pkg.Name()
-				if pkg.Goroot {
-					label = node.Func.Pkg.Pkg.Path()
-				}
-				key := node.Func.Pkg.Pkg.Path()
-				if _, ok := c.Clusters[key]; !ok {
-					c.Clusters[key] = &dotCluster{
-						ID:       key,
-						Clusters: make(map[string]*dotCluster),
-						Attrs: dotAttrs{
-							"penwidth":  "0.8",
-							"fontsize":  "16",
-							"label":     label,
-							"style":     "filled",
-							"fillcolor": "snow",
-						},
-					}
-					if pkg.Goroot {
-						c.Clusters[key].Attrs["fillcolor"] = "#E0FFE1"
-					}
-				}
-				c = c.Clusters[key]
-			}
 
-			// group by type
-			if groupType && sign.Recv() != nil {
-				label := strings.Split(node.Func.RelString(node.Func.Pkg.Pkg), ".")[0]
-				fillclr := "lemonchiffon"
-				if node.Func.Pkg.Pkg.Name() == focusPkg {
-					fillclr = "lavender"
-				}
-				key := sign.Recv().Type().String()
-				if _, ok := c.Clusters[key]; !ok {
-					c.Clusters[key] = &dotCluster{
-						ID:       key,
-						Clusters: make(map[string]*dotCluster),
-						Attrs: dotAttrs{
-							"penwidth":  "0.5",
-							"fontsize":  "15",
-							"fontcolor": "#222222",
-							"label":     label,
-							"labelloc":  "b",
-							"style":     "rounded,filled",
-							"fillcolor": fillclr,
-						},
-					}
-					if pkg.Goroot {
-						c.Clusters[key].Attrs["fillcolor"] = "#c4ecc4"
-					}
-				}
-				c = c.Clusters[key]
-			}
-
-			n := &dotNode{
-				ID:    node.Func.String(),
-				Attrs: attrs,
-			}
-
-			if c != nil {
-				c.Nodes = append(c.Nodes, n)
-			} else {
-				nodes = append(nodes, n)
+			n := &Node{
+				ID:        key,
+				Label:     node.Func.RelString(node.Func.Pkg.Pkg),
+				Pkg:       node.Func.Pkg.Pkg.Path(),
+				PkgName:   node.Func.Pkg.Pkg.Name(),
+				Recv:      recv,
+				IsClosure: node.Func.Parent() != nil,
+				Exported:  node.Func.Object() != nil && node.Func.Object().Exported(),
+				Goroot:    pkg.Goroot,
+				InFocus:   node.Func.Pkg.Pkg.Name() == focusPkg,
 			}
 
+			graph.Nodes = append(graph.Nodes, n)
 			nodeMap[key] = n
 			return n
 		}
 		callerNode := sprintNode(edge.Caller)
 		calleeNode := sprintNode(edge.Callee)
 
-		// edges
-		attrs := make(dotAttrs)
-
-		// dynamic call
-		if edge.Site != nil && edge.Site.Common().StaticCallee() == nil {
-			attrs["style"] = "dashed"
+		e := &Edge{
+			From:    callerNode,
+			To:      calleeNode,
+			Dynamic: edge.Site != nil && edge.Site.Common().StaticCallee() == nil,
 		}
 
 		// go & defer calls
 		switch edge.Site.(type) {
 		case *ssa.Go:
-			attrs["arrowhead"] = "normalnoneodot"
+			e.Go = true
 		case *ssa.Defer:
-			attrs["arrowhead"] = "normalnoneodiamond"
+			e.Defer = true
 		}
 
 		// colorize calls outside focused pkg
 		if focusPkg != "" &&
 			(calleePkg.Name() != focusPkg || callerPkg.Name() != focusPkg) {
-			attrs["color"] = "saddlebrown"
-		}
-
-		e := &dotEdge{
-			From:  callerNode,
-			To:    calleeNode,
-			Attrs: attrs,
+			e.CrossFocus = true
 		}
 
 		// omit duplicate calls
-		key := fmt.Sprintf("%s = %s => %s", caller.Func, edge.Description(), callee.Func)
-		if _, ok := edgeMap[key]; !ok {
-			edges = append(edges, e)
-			edgeMap[key] = e
+		dedupeKey := fmt.Sprintf("%s = %s => %s", caller.Func, edge.Description(), callee.Func)
+		if !edgeSeen[dedupeKey] {
+			graph.Edges = append(graph.Edges, e)
+			edgeSeen[dedupeKey] = true
 		}
 
 		return nil
@@ -232,19 +140,19 @@ func printOutput(mainPkg *types.Package, cg *callgraph.Graph, focusPkg, limitPat
 		return err
 	}
 
-	logf("%d edges", len(edges))
-
-	dot := &dotGraph{
-		Title:   mainPkg.Path(),
-		Minlen:  minlen,
-		Cluster: cluster,
-		Nodes:   nodes,
-		Edges:   edges,
-		Options: map[string]string{
-			"minlen":  fmt.Sprint(minlen),
-			"nodesep": fmt.Sprint(nodesep),
-		},
+	logf("%d edges", len(graph.Edges))
+
+	if profilePath != "" {
+		w, err := loadProfile(profilePath, profileKind)
+		if err != nil {
+			return fmt.Errorf("profile: %w", err)
+		}
+		applyProfile(graph, w)
 	}
 
-	return WriteDot(output, dot)
+	f, err := formatterFor(outputFile, format)
+	if err != nil {
+		return err
+	}
+	return f.Render(output, graph)
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// profilePath and profileKind select which pprof profile, if any, to
+// overlay onto the call graph, and which sample type within it to use
+// (cpu, alloc_space, inuse_space, ...).
+var profilePath string
+var profileKind = "cpu"
+
+// profileWeights holds the per-function flat/cumulative sample aggregates
+// pulled out of a pprof profile, keyed the same way Graph.Node.ID is
+// (node.Func.String()), so applyProfile can join them directly.
+type profileWeights struct {
+	flat       map[string]int64
+	cum        map[string]int64
+	totalCum   int64
+	unresolved int
+}
+
+// loadProfile parses path with github.com/google/pprof/profile and
+// aggregates flat and cumulative samples per function for the requested
+// sample kind.
+func loadProfile(path, kind string) (*profileWeights, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := profile.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleIndex := -1
+	for i, st := range p.SampleType {
+		if st.Type == kind {
+			sampleIndex = i
+			break
+		}
+	}
+	if sampleIndex == -1 {
+		available := make([]string, len(p.SampleType))
+		for i, st := range p.SampleType {
+			available[i] = st.Type
+		}
+		return nil, fmt.Errorf("profile: sample type %q not found in %s (available: %s)", kind, path, strings.Join(available, ", "))
+	}
+
+	w := &profileWeights{flat: make(map[string]int64), cum: make(map[string]int64)}
+	for _, sample := range p.Sample {
+		v := sample.Value[sampleIndex]
+		if len(sample.Location) == 0 {
+			w.unresolved++
+			continue
+		}
+
+		// flat: innermost frame only
+		if fn := funcKey(sample.Location[0]); fn != "" {
+			w.flat[fn] += v
+		} else {
+			w.unresolved++
+		}
+
+		// cumulative: every distinct frame on the stack
+		seen := make(map[string]bool)
+		for _, loc := range sample.Location {
+			fn := funcKey(loc)
+			if fn == "" || seen[fn] {
+				continue
+			}
+			seen[fn] = true
+			w.cum[fn] += v
+		}
+
+		w.totalCum += v
+	}
+	return w, nil
+}
+
+// closureSuffix matches the runtime/pprof symbolization of a closure and
+// any nesting below it: the outermost closure is marked ".funcN", but
+// runtime symbolization drops the "func" for every level below that
+// (pkg.Outer.func1.1.2 for a doubly-nested closure), while go/ssa names
+// every level with its own "$N" (pkg.Outer$1$1$2). funcKey rewrites the
+// whole trailing run so it matches node.Func.String() at any nesting depth.
+var closureSuffix = regexp.MustCompile(`\.func\d+(?:\.\d+)*$`)
+
+// funcKey reduces a pprof Location to the form node.Func.String() produces,
+// so it can be joined against Graph.Node.ID directly. Only the first Line of
+// a location is used: for a location with inlined calls that is the
+// innermost inlined function, and the functions it was inlined into are not
+// credited for that location.
+func funcKey(loc *profile.Location) string {
+	if len(loc.Line) == 0 || loc.Line[0].Function == nil {
+		return ""
+	}
+	name := loc.Line[0].Function.Name
+	return closureSuffix.ReplaceAllStringFunc(name, func(m string) string {
+		segs := strings.Split(strings.TrimPrefix(m, "."), ".")
+		var b strings.Builder
+		for _, seg := range segs {
+			b.WriteByte('$')
+			b.WriteString(strings.TrimPrefix(seg, "func"))
+		}
+		return b.String()
+	})
+}
+
+// applyProfile overlays w onto g: every Node gets a flat%/cum% share of the
+// profile's total, which the dot formatter turns into an xlabel, a
+// heat-scaled fillcolor and penwidth-scaled edges. The share of sample
+// weight whose innermost frame joined no Node (unsymbolized frames,
+// functions outside the built SSA graph) is reported once as a diagnostic
+// summary.
+func applyProfile(g *Graph, w *profileWeights) {
+	if w.totalCum == 0 {
+		return
+	}
+	var matchedFlat int64
+	for _, n := range g.Nodes {
+		flat := w.flat[n.ID]
+		cum := w.cum[n.ID]
+		n.FlatPercent = 100 * float64(flat) / float64(w.totalCum)
+		n.CumPercent = 100 * float64(cum) / float64(w.totalCum)
+		n.CumSamples = cum
+		matchedFlat += flat
+	}
+	unmatchedPercent := 100 * float64(w.totalCum-matchedFlat) / float64(w.totalCum)
+	logf("profile: %d samples unresolved (no stack or no function for the innermost frame), %.1f%% of sample weight has an innermost frame with no matching node in the call graph", w.unresolved, unmatchedPercent)
+}
+
+// heatColor maps a 0-100 cumulative percentage to a cool-to-hot fillcolor
+// on a log scale, so a handful of very hot functions don't wash out the
+// rest of the graph the way a linear scale would.
+func heatColor(cumPercent float64) string {
+	if cumPercent <= 0 {
+		return "wheat"
+	}
+	t := math.Log1p(cumPercent) / math.Log1p(100)
+	switch {
+	case t > 0.75:
+		return "#d73027"
+	case t > 0.5:
+		return "#fc8d59"
+	case t > 0.25:
+		return "#fee08b"
+	default:
+		return "#91cf60"
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+// TestFuncKeyNestedClosure checks that funcKey rewrites pprof's closure
+// symbolization to go/ssa's "$N" naming at every nesting depth, not just
+// the outermost ".funcN" marker.
+func TestFuncKeyNestedClosure(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"main.plainFunc", "main.plainFunc"},
+		{"main.outer.func1", "main.outer$1"},
+		{"main.outer.func1.1", "main.outer$1$1"},
+		{"main.outer.func1.1.2", "main.outer$1$1$2"},
+	}
+
+	for _, c := range cases {
+		loc := &profile.Location{
+			Line: []profile.Line{{Function: &profile.Function{Name: c.name}}},
+		}
+		if got := funcKey(loc); got != c.want {
+			t.Errorf("funcKey(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}